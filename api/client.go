@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// Client issues authenticated PAPI requests against an Isilon cluster and
+// decodes their JSON bodies into resp. On a status of 400 or above it
+// returns an *Error built from the cluster's decoded PAPI error body so
+// callers can classify failures (see IsNotFound) instead of
+// string-matching. The client itself does not log; the Isi* helpers log
+// each call they make through it via LogRequest/LogResponse.
+type Client interface {
+	Get(ctx context.Context, p, name string, params, headers map[string]string, resp interface{}) error
+	Post(ctx context.Context, p, name string, params, headers map[string]string, body, resp interface{}) error
+	Put(ctx context.Context, p, name string, params, headers map[string]string, body, resp interface{}) error
+	Delete(ctx context.Context, p, name string, params, headers map[string]string, resp interface{}) error
+
+	// User and Group are the principal names applied to resources this
+	// client creates, absent a per-call OwnershipOptions override.
+	User() string
+	Group() string
+}
+
+// client is the default Client, talking PAPI over HTTPS.
+type client struct {
+	endpoint   string
+	user       string
+	password   string
+	group      string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client that authenticates to endpoint as user/
+// password, applying group as the default group for volumes it creates.
+// httpClient may be nil, in which case http.DefaultClient is used.
+func NewClient(endpoint, user, password, group string, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &client{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		user:       user,
+		password:   password,
+		group:      group,
+		httpClient: httpClient,
+	}
+}
+
+func (c *client) User() string  { return c.user }
+func (c *client) Group() string { return c.group }
+
+func (c *client) Get(ctx context.Context, p, name string, params, headers map[string]string, resp interface{}) error {
+	return c.do(ctx, http.MethodGet, p, name, params, headers, nil, resp)
+}
+
+func (c *client) Post(ctx context.Context, p, name string, params, headers map[string]string, body, resp interface{}) error {
+	return c.do(ctx, http.MethodPost, p, name, params, headers, body, resp)
+}
+
+func (c *client) Put(ctx context.Context, p, name string, params, headers map[string]string, body, resp interface{}) error {
+	return c.do(ctx, http.MethodPut, p, name, params, headers, body, resp)
+}
+
+func (c *client) Delete(ctx context.Context, p, name string, params, headers map[string]string, resp interface{}) error {
+	return c.do(ctx, http.MethodDelete, p, name, params, headers, nil, resp)
+}
+
+func (c *client) do(
+	ctx context.Context,
+	method, p, name string,
+	params, headers map[string]string,
+	body, resp interface{}) error {
+
+	u, err := url.Parse(c.endpoint + "/" + path.Join(p, name))
+	if err != nil {
+		return err
+	}
+
+	if len(params) > 0 {
+		q := u.Query()
+		for k, v := range params {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var reqBody *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	} else {
+		reqBody = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.user, c.password)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResp.StatusCode >= 400 {
+		return decodeError(httpResp.StatusCode, respBody)
+	}
+
+	if resp == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, resp)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Error is returned when the cluster responds with a decoded PAPI JSON
+// error body, letting callers classify failures (e.g. not-found) instead
+// of string-matching error text.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("isilon: %s (code=%s, status=%d)", e.Message, e.Code, e.StatusCode)
+}
+
+// IsNotFound reports whether err represents a 404 response from the
+// cluster.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == 404
+}
+
+// papiErrorBody mirrors the {"errors": [...]} envelope the PAPI returns
+// alongside a non-2xx status.
+type papiErrorBody struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// decodeError builds an *Error from a PAPI response's status code and raw
+// body, falling back to the raw body text if it isn't the expected
+// {"errors": [...]} envelope.
+func decodeError(statusCode int, body []byte) error {
+	var parsed papiErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil && len(parsed.Errors) > 0 {
+		return &Error{
+			StatusCode: statusCode,
+			Code:       parsed.Errors[0].Code,
+			Message:    parsed.Errors[0].Message,
+		}
+	}
+	return &Error{
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/thecodeteam/gournal"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, so every gournal entry
+// logged while servicing a single logical operation can be correlated
+// across the request and its response, even when the operation fans out
+// into several PAPI round-trips.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID previously attached with WithRequestID,
+// or "" if none was set.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// redactedHeaders lists the header names whose values are never logged.
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+}
+
+// redactHeaders returns headers with the value of any sensitive header
+// (Authorization, Cookie) replaced by "REDACTED".
+func redactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			redacted[k] = "REDACTED"
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// LogRequest emits a DEBUG gournal entry describing an outgoing PAPI
+// request. Field construction is deferred to a closure stashed on the
+// context via gournal.FieldsKey(), so it is skipped entirely unless the
+// context's level permits a DEBUG entry to be emitted.
+func LogRequest(ctx context.Context, method, path string, headers map[string]string) {
+	fields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"requestID": RequestID(ctx),
+			"method":    method,
+			"path":      path,
+			"headers":   redactHeaders(headers),
+		}
+	}
+	gournal.Debug(context.WithValue(ctx, gournal.FieldsKey(), fields), "isilon: sending request")
+}
+
+// errorFields returns the extra fields LogResponse adds to a failed
+// request's log entry: the decoded PAPI status code and error code when
+// err is an *Error, or nothing for any other error (e.g. a transport
+// failure that never reached the cluster).
+func errorFields(err error) map[string]interface{} {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"statusCode": apiErr.StatusCode,
+		"code":       apiErr.Code,
+	}
+}
+
+// LogResponse emits a DEBUG entry for a successful PAPI response, or an
+// ERROR entry carrying the decoded PAPI error body when err is non-nil.
+func LogResponse(ctx context.Context, method, path string, elapsed time.Duration, err error) {
+	fields := func() map[string]interface{} {
+		f := map[string]interface{}{
+			"requestID": RequestID(ctx),
+			"method":    method,
+			"path":      path,
+			"elapsed":   elapsed.String(),
+		}
+		for k, v := range errorFields(err) {
+			f[k] = v
+		}
+		return f
+	}
+
+	ctx = context.WithValue(ctx, gournal.FieldsKey(), fields)
+	if err != nil {
+		gournal.Error(ctx, "isilon: %s %s failed: %v", method, path, err)
+		return
+	}
+	gournal.Debug(ctx, "isilon: %s %s succeeded", method, path)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorFields(t *testing.T) {
+	t.Run("api.Error contributes statusCode and code", func(t *testing.T) {
+		fields := errorFields(&Error{StatusCode: 404, Code: "AEC_NOT_FOUND", Message: "not found"})
+		if fields["statusCode"] != 404 {
+			t.Fatalf("statusCode = %v, want 404", fields["statusCode"])
+		}
+		if fields["code"] != "AEC_NOT_FOUND" {
+			t.Fatalf("code = %v, want AEC_NOT_FOUND", fields["code"])
+		}
+	})
+
+	t.Run("other errors contribute nothing", func(t *testing.T) {
+		if fields := errorFields(errors.New("connection refused")); fields != nil {
+			t.Fatalf("expected no fields for a non-*Error, got %v", fields)
+		}
+	})
+
+	t.Run("nil error contributes nothing", func(t *testing.T) {
+		if fields := errorFields(nil); fields != nil {
+			t.Fatalf("expected no fields for a nil error, got %v", fields)
+		}
+	})
+}
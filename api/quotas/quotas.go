@@ -0,0 +1,153 @@
+package quotas
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+)
+
+const quotasPath = "platform/1/quota/quotas"
+
+// Thresholds holds the hard, soft, and advisory size limits of a quota, in
+// bytes. A threshold of 0 leaves that limit unset.
+type Thresholds struct {
+	Hard     int64 `json:"hard,omitempty"`
+	Soft     int64 `json:"soft,omitempty"`
+	Advisory int64 `json:"advisory,omitempty"`
+}
+
+// Usage reports a quota's current consumption, in bytes.
+type Usage struct {
+	Logical  int64 `json:"logical"`
+	Physical int64 `json:"physical"`
+}
+
+// IsiQuota represents a single SmartQuotas quota as returned by the PAPI.
+type IsiQuota struct {
+	ID               string      `json:"id"`
+	Path             string      `json:"path"`
+	Type             string      `json:"type"`
+	IncludeSnapshots bool        `json:"include_snapshots"`
+	Thresholds       *Thresholds `json:"thresholds"`
+	Usage            *Usage      `json:"usage"`
+}
+
+type createIsiQuotaResp struct {
+	ID string `json:"id"`
+}
+
+type getIsiQuotasResp struct {
+	Quotas []*IsiQuota `json:"quotas"`
+	Total  int         `json:"total"`
+	Resume string      `json:"resume,omitempty"`
+}
+
+// CreateIsiQuota installs a directory quota on path, enforcing
+// hardThreshold as a hard limit and softThreshold/advisoryThreshold as
+// warning levels. When includeSnapshots is true, snapshot usage counts
+// against the quota. It returns the new quota's ID.
+func CreateIsiQuota(
+	ctx context.Context,
+	client api.Client,
+	path string,
+	hardThreshold, softThreshold, advisoryThreshold int64,
+	includeSnapshots bool) (id string, err error) {
+
+	data := &struct {
+		Path             string      `json:"path"`
+		Type             string      `json:"type"`
+		IncludeSnapshots bool        `json:"include_snapshots"`
+		Thresholds       *Thresholds `json:"thresholds"`
+	}{
+		Path:             path,
+		Type:             "directory",
+		IncludeSnapshots: includeSnapshots,
+		Thresholds: &Thresholds{
+			Hard:     hardThreshold,
+			Soft:     softThreshold,
+			Advisory: advisoryThreshold,
+		},
+	}
+
+	// PAPI call: POST https://1.2.3.4:8080/platform/1/quota/quotas
+	var resp *createIsiQuotaResp
+	api.LogRequest(ctx, "POST", quotasPath, nil)
+	start := time.Now()
+	err = client.Post(ctx, quotasPath, "", nil, nil, data, &resp)
+	api.LogResponse(ctx, "POST", quotasPath, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// GetIsiQuota queries a single quota by ID.
+func GetIsiQuota(
+	ctx context.Context,
+	client api.Client,
+	id string) (quota *IsiQuota, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/quota/quotas/id
+	var resp *getIsiQuotasResp
+	api.LogRequest(ctx, "GET", quotasPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, quotasPath, id, nil, nil, &resp)
+	api.LogResponse(ctx, "GET", quotasPath, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Quotas) == 0 {
+		return nil, fmt.Errorf("quota %q not found", id)
+	}
+	return resp.Quotas[0], nil
+}
+
+// ListIsiQuotasByPath queries the quotas applied to a directory path.
+func ListIsiQuotasByPath(
+	ctx context.Context,
+	client api.Client,
+	path string) (resp *getIsiQuotasResp, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/quota/quotas?path=/path/to/volume
+	api.LogRequest(ctx, "GET", quotasPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, quotasPath, "", map[string]string{"path": path}, nil, &resp)
+	api.LogResponse(ctx, "GET", quotasPath, time.Since(start), err)
+	return resp, err
+}
+
+// UpdateIsiQuotaSize changes the hard threshold of an existing quota.
+func UpdateIsiQuotaSize(
+	ctx context.Context,
+	client api.Client,
+	id string,
+	hardThreshold int64) error {
+
+	data := &struct {
+		Thresholds *Thresholds `json:"thresholds"`
+	}{Thresholds: &Thresholds{Hard: hardThreshold}}
+
+	// PAPI call: PUT https://1.2.3.4:8080/platform/1/quota/quotas/id
+	api.LogRequest(ctx, "PUT", quotasPath, nil)
+	start := time.Now()
+	err := client.Put(ctx, quotasPath, id, nil, nil, data, nil)
+	api.LogResponse(ctx, "PUT", quotasPath, time.Since(start), err)
+	return err
+}
+
+// DeleteIsiQuota removes a quota by ID.
+func DeleteIsiQuota(
+	ctx context.Context,
+	client api.Client,
+	id string) error {
+
+	// PAPI call: DELETE https://1.2.3.4:8080/platform/1/quota/quotas/id
+	api.LogRequest(ctx, "DELETE", quotasPath, nil)
+	start := time.Now()
+	err := client.Delete(ctx, quotasPath, id, nil, nil, nil)
+	api.LogResponse(ctx, "DELETE", quotasPath, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+	v2 "github.com/emccode/goisilon/api/v2"
+)
+
+// ExportOptions configures the NFS export created by ExportIsiVolume.
+type ExportOptions struct {
+	Clients         []string
+	RootClients     []string
+	ReadOnlyClients []string
+	MapRoot         *v2.IsiNfsExportMap
+	MapAll          *v2.IsiNfsExportMap
+	SecurityFlavors []string
+}
+
+// ExportIsiVolume creates an NFS export rooted at the given volume, making
+// it mountable by clients, and returns the new export's ID.
+func ExportIsiVolume(
+	ctx context.Context,
+	client api.Client,
+	name string,
+	opts *ExportOptions) (exportID int, err error) {
+
+	path := fmt.Sprintf("/%s/%s", realNamespacePath(client), name)
+
+	nfsOpts := &v2.IsiNfsExportOptions{Paths: []string{path}}
+	if opts != nil {
+		nfsOpts.Clients = opts.Clients
+		nfsOpts.RootClients = opts.RootClients
+		nfsOpts.ReadOnlyClients = opts.ReadOnlyClients
+		nfsOpts.MapRoot = opts.MapRoot
+		nfsOpts.MapAll = opts.MapAll
+		nfsOpts.SecurityFlavors = opts.SecurityFlavors
+	}
+
+	return v2.CreateIsiNfsExport(ctx, client, nfsOpts)
+}
+
+// UnexportIsiVolume removes the NFS export previously created for the
+// named volume by ExportIsiVolume, resolving the export by the volume's
+// namespace path so callers (e.g. a CSI NodeUnpublish flow) don't need to
+// have tracked the export ID themselves.
+func UnexportIsiVolume(
+	ctx context.Context,
+	client api.Client,
+	name string) error {
+
+	path := fmt.Sprintf("/%s/%s", realNamespacePath(client), name)
+
+	export, err := v2.GetIsiNfsExportByPath(ctx, client, path)
+	if err != nil {
+		return err
+	}
+
+	return v2.DeleteIsiNfsExport(ctx, client, export.ID)
+}
+
+// AddExportClients attaches additional clients to an existing export
+// without racing on a full PUT of its current client list.
+func AddExportClients(
+	ctx context.Context,
+	client api.Client,
+	exportID int,
+	clients []string) error {
+
+	return v2.AddIsiNfsExportClients(ctx, client, exportID, clients)
+}
+
+// RemoveExportClients detaches clients from an existing export.
+func RemoveExportClients(
+	ctx context.Context,
+	client api.Client,
+	exportID int,
+	clients []string) error {
+
+	return v2.RemoveIsiNfsExportClients(ctx, client, exportID, clients)
+}
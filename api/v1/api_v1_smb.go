@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+)
+
+const smbSharesPath = "platform/1/protocols/smb/shares"
+
+// IsiSmbShare represents a single SMB share as returned by the PAPI.
+type IsiSmbShare struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// IsiSmbShareOptions carries the fields of an SMB share that callers may
+// set on create or update.
+type IsiSmbShareOptions struct {
+	Name string
+	Path string
+}
+
+type isiSmbShareBody struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+func newIsiSmbShareBody(opts *IsiSmbShareOptions) *isiSmbShareBody {
+	if opts == nil {
+		return &isiSmbShareBody{}
+	}
+	return &isiSmbShareBody{Name: opts.Name, Path: opts.Path}
+}
+
+type createIsiSmbShareResp struct {
+	ID string `json:"id"`
+}
+
+type getIsiSmbSharesResp struct {
+	Shares []*IsiSmbShare `json:"shares"`
+	Total  int            `json:"total"`
+	Resume string         `json:"resume,omitempty"`
+}
+
+// CreateIsiSmbShare creates a new SMB share and returns its ID.
+func CreateIsiSmbShare(
+	ctx context.Context,
+	client api.Client,
+	opts *IsiSmbShareOptions) (id string, err error) {
+
+	// PAPI call: POST https://1.2.3.4:8080/platform/1/protocols/smb/shares
+	var resp *createIsiSmbShareResp
+	api.LogRequest(ctx, "POST", smbSharesPath, nil)
+	start := time.Now()
+	err = client.Post(ctx, smbSharesPath, "", nil, nil, newIsiSmbShareBody(opts), &resp)
+	api.LogResponse(ctx, "POST", smbSharesPath, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// GetIsiSmbShares queries all SMB shares on the cluster.
+func GetIsiSmbShares(
+	ctx context.Context,
+	client api.Client) (resp *getIsiSmbSharesResp, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/protocols/smb/shares
+	api.LogRequest(ctx, "GET", smbSharesPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, smbSharesPath, "", nil, nil, &resp)
+	api.LogResponse(ctx, "GET", smbSharesPath, time.Since(start), err)
+	return resp, err
+}
+
+// GetIsiSmbShare queries a single SMB share by ID.
+func GetIsiSmbShare(
+	ctx context.Context,
+	client api.Client,
+	id string) (share *IsiSmbShare, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/protocols/smb/shares/id
+	var resp *getIsiSmbSharesResp
+	api.LogRequest(ctx, "GET", smbSharesPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, smbSharesPath, id, nil, nil, &resp)
+	api.LogResponse(ctx, "GET", smbSharesPath, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Shares) == 0 {
+		return nil, fmt.Errorf("smb share %q not found", id)
+	}
+	return resp.Shares[0], nil
+}
+
+// UpdateIsiSmbShare updates an existing SMB share.
+func UpdateIsiSmbShare(
+	ctx context.Context,
+	client api.Client,
+	id string,
+	opts *IsiSmbShareOptions) error {
+
+	// PAPI call: PUT https://1.2.3.4:8080/platform/1/protocols/smb/shares/id
+	api.LogRequest(ctx, "PUT", smbSharesPath, nil)
+	start := time.Now()
+	err := client.Put(ctx, smbSharesPath, id, nil, nil, newIsiSmbShareBody(opts), nil)
+	api.LogResponse(ctx, "PUT", smbSharesPath, time.Since(start), err)
+	return err
+}
+
+// DeleteIsiSmbShare removes an SMB share by ID.
+func DeleteIsiSmbShare(
+	ctx context.Context,
+	client api.Client,
+	id string) error {
+
+	// PAPI call: DELETE https://1.2.3.4:8080/platform/1/protocols/smb/shares/id
+	api.LogRequest(ctx, "DELETE", smbSharesPath, nil)
+	start := time.Now()
+	err := client.Delete(ctx, smbSharesPath, id, nil, nil, nil)
+	api.LogResponse(ctx, "DELETE", smbSharesPath, time.Since(start), err)
+	return err
+}
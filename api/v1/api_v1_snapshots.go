@@ -0,0 +1,268 @@
+package v1
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+)
+
+const snapshotsPath = "platform/1/snapshot/snapshots"
+
+// IsiSnapshot represents a single SnapshotIQ snapshot as returned by the
+// PAPI.
+type IsiSnapshot struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Created int64  `json:"created"`
+	Alias   string `json:"alias,omitempty"`
+}
+
+type getIsiSnapshotsResp struct {
+	Snapshots []*IsiSnapshot `json:"snapshots"`
+	Total     int            `json:"total"`
+	Resume    string         `json:"resume,omitempty"`
+}
+
+// CreateIsiSnapshot creates a point-in-time snapshot of a volume.
+func CreateIsiSnapshot(
+	ctx context.Context,
+	client api.Client,
+	volumeName, snapshotName string) (resp *getIsiSnapshotsResp, err error) {
+
+	// PAPI call: POST https://1.2.3.4:8080/platform/1/snapshot/snapshots
+	//            {name: "snapshot_name", path: "/path/to/volumes/volume_name"}
+
+	data := &struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	}{
+		Name: snapshotName,
+		Path: fmt.Sprintf("/%s/%s", realNamespacePath(client), volumeName),
+	}
+
+	api.LogRequest(ctx, "POST", snapshotsPath, nil)
+	start := time.Now()
+	err = client.Post(ctx, snapshotsPath, "", nil, nil, data, &resp)
+	api.LogResponse(ctx, "POST", snapshotsPath, time.Since(start), err)
+	return resp, err
+}
+
+// GetIsiSnapshots queries a list of all snapshots on the cluster.
+func GetIsiSnapshots(
+	ctx context.Context,
+	client api.Client) (resp *getIsiSnapshotsResp, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/snapshot/snapshots
+	api.LogRequest(ctx, "GET", snapshotsPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, snapshotsPath, "", nil, nil, &resp)
+	api.LogResponse(ctx, "GET", snapshotsPath, time.Since(start), err)
+	return resp, err
+}
+
+// GetIsiSnapshotsByPath queries the snapshots that were taken of the given
+// directory path.
+func GetIsiSnapshotsByPath(
+	ctx context.Context,
+	client api.Client,
+	path string) (resp *getIsiSnapshotsResp, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/1/snapshot/snapshots?path=/path/to/volume
+	api.LogRequest(ctx, "GET", snapshotsPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, snapshotsPath, "", map[string]string{"path": path}, nil, &resp)
+	api.LogResponse(ctx, "GET", snapshotsPath, time.Since(start), err)
+	return resp, err
+}
+
+// DeleteIsiSnapshot removes a snapshot from the cluster by name.
+func DeleteIsiSnapshot(
+	ctx context.Context,
+	client api.Client,
+	snapshotName string) error {
+
+	// PAPI call: DELETE https://1.2.3.4:8080/platform/1/snapshot/snapshots/snapshot_name
+	api.LogRequest(ctx, "DELETE", snapshotsPath, nil)
+	start := time.Now()
+	err := client.Delete(ctx, snapshotsPath, snapshotName, nil, nil, nil)
+	api.LogResponse(ctx, "DELETE", snapshotsPath, time.Since(start), err)
+	return err
+}
+
+// isiSnapshotVolumeName looks up snapshotName and returns the name of the
+// volume it was taken of, derived from the snapshot's recorded Path. This
+// lets callers that only know a snapshot by name avoid repeating (and
+// potentially mismatching) the volume name the snapshot already carries.
+func isiSnapshotVolumeName(ctx context.Context, client api.Client, snapshotName string) (string, error) {
+	resp, err := GetIsiSnapshots(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	for _, snap := range resp.Snapshots {
+		if snap.Name == snapshotName {
+			return path.Base(snap.Path), nil
+		}
+	}
+
+	return "", fmt.Errorf("snapshot %q not found", snapshotName)
+}
+
+// CopyIsiSnapshot provisions a new volume on the cluster from an existing
+// snapshot, mirroring CopyIsiVolume but sourcing the copy from the
+// snapshot's ".snapshot" view of the namespace instead of the live path.
+// The source volume is looked up from sourceSnapshot itself rather than
+// taken as a caller-supplied argument, so a stale or mismatched volume
+// name can't silently copy from the wrong (or a nonexistent) path.
+func CopyIsiSnapshot(
+	ctx context.Context,
+	client api.Client,
+	sourceSnapshot, destinationName string) (resp *getIsiVolumesResp, err error) {
+
+	// PAPI call: PUT https://1.2.3.4:8080/namespace/path/to/volumes/destination_volume_name
+	//            x-isi-ifs-copy-source: /path/to/volumes/.snapshot/source_snapshot/source_volume
+
+	sourceVolume, err := isiSnapshotVolumeName(ctx, client, sourceSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	nsPath := realNamespacePath(client)
+	headers := map[string]string{
+		"x-isi-ifs-copy-source": fmt.Sprintf(
+			"/%s/.snapshot/%s/%s", nsPath, sourceSnapshot, sourceVolume),
+	}
+
+	api.LogRequest(ctx, "PUT", nsPath, headers)
+	start := time.Now()
+	err = client.Put(ctx, nsPath, destinationName, nil, headers, nil, &resp)
+	api.LogResponse(ctx, "PUT", nsPath, time.Since(start), err)
+	return resp, err
+}
+
+// IsiSnapshotGroupResult is the outcome of snapshotting a single volume as
+// part of a CreateIsiSnapshotGroup call.
+type IsiSnapshotGroupResult struct {
+	VolumeName   string
+	SnapshotName string
+	Err          error
+}
+
+// isiSnapshotGroupPrefix returns the shared name prefix used to tag every
+// snapshot belonging to the group identified by label.
+func isiSnapshotGroupPrefix(label string) string {
+	return label + "--"
+}
+
+func isiSnapshotGroupName(label, volumeName string) string {
+	return isiSnapshotGroupPrefix(label) + volumeName
+}
+
+// CreateIsiSnapshotGroup mimics a VolumeGroupSnapshot on a cluster that has
+// no native group-snapshot primitive: it snapshots each of volumeNames
+// serially, tagging every snapshot's name with the shared label so the
+// group can later be listed or deleted as a unit via ListIsiSnapshotGroup
+// and DeleteIsiSnapshotGroup. If any individual create fails, the
+// snapshots that already succeeded are best-effort deleted and a combined
+// error is returned alongside the partial results.
+func CreateIsiSnapshotGroup(
+	ctx context.Context,
+	client api.Client,
+	label string,
+	volumeNames []string) (results []*IsiSnapshotGroupResult, err error) {
+
+	results = make([]*IsiSnapshotGroupResult, 0, len(volumeNames))
+
+	for _, volumeName := range volumeNames {
+		snapshotName := isiSnapshotGroupName(label, volumeName)
+		_, createErr := CreateIsiSnapshot(ctx, client, volumeName, snapshotName)
+		results = append(results, &IsiSnapshotGroupResult{
+			VolumeName:   volumeName,
+			SnapshotName: snapshotName,
+			Err:          createErr,
+		})
+
+		if createErr != nil {
+			return results, rollbackIsiSnapshotGroup(ctx, client, label, results, createErr)
+		}
+	}
+
+	return results, nil
+}
+
+// rollbackIsiSnapshotGroup best-effort deletes every snapshot in results
+// that was created successfully, then folds the original failure and any
+// rollback failures into a single error.
+func rollbackIsiSnapshotGroup(
+	ctx context.Context,
+	client api.Client,
+	label string,
+	results []*IsiSnapshotGroupResult,
+	cause error) error {
+
+	msg := fmt.Sprintf("failed to create snapshot group %q: %v", label, cause)
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if derr := DeleteIsiSnapshot(ctx, client, r.SnapshotName); derr != nil {
+			msg = fmt.Sprintf("%s; failed to roll back snapshot %q: %v", msg, r.SnapshotName, derr)
+		}
+	}
+
+	return fmt.Errorf("%s", msg)
+}
+
+// ListIsiSnapshotGroup queries the snapshots tagged with label by a prior
+// CreateIsiSnapshotGroup call.
+func ListIsiSnapshotGroup(
+	ctx context.Context,
+	client api.Client,
+	label string) (snapshots []*IsiSnapshot, err error) {
+
+	resp, err := GetIsiSnapshots(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := isiSnapshotGroupPrefix(label)
+	for _, snap := range resp.Snapshots {
+		if len(snap.Name) >= len(prefix) && snap.Name[:len(prefix)] == prefix {
+			snapshots = append(snapshots, snap)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// DeleteIsiSnapshotGroup removes every snapshot tagged with label, returning
+// a combined error if any individual delete fails.
+func DeleteIsiSnapshotGroup(
+	ctx context.Context,
+	client api.Client,
+	label string) error {
+
+	snapshots, err := ListIsiSnapshotGroup(ctx, client, label)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshot group %q: %v", label, err)
+	}
+
+	var msg string
+	for _, snap := range snapshots {
+		if derr := DeleteIsiSnapshot(ctx, client, snap.Name); derr != nil {
+			msg = fmt.Sprintf("%s; failed to delete snapshot %q: %v", msg, snap.Name, derr)
+		}
+	}
+
+	if msg != "" {
+		return fmt.Errorf("failed to delete snapshot group %q%s", label, msg)
+	}
+
+	return nil
+}
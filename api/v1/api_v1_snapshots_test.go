@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestCreateIsiSnapshotGroup_RollsBackOnPartialFailure(t *testing.T) {
+	var deleted []string
+	postCount := 0
+
+	client := &fakeClient{
+		postFunc: func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+			postCount++
+			if postCount == 2 {
+				return errors.New("quota exceeded")
+			}
+			return nil
+		},
+		deleteFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+			deleted = append(deleted, name)
+			return nil
+		},
+	}
+
+	_, err := CreateIsiSnapshotGroup(context.Background(), client, "grp", []string{"vol-1", "vol-2", "vol-3"})
+	if err == nil {
+		t.Fatal("expected an error from the failed create")
+	}
+
+	if postCount != 2 {
+		t.Fatalf("expected vol-3 to never be attempted after vol-2 failed, got %d POSTs", postCount)
+	}
+
+	want := isiSnapshotGroupName("grp", "vol-1")
+	if len(deleted) != 1 || deleted[0] != want {
+		t.Fatalf("expected only the successful vol-1 snapshot to be rolled back, got %v", deleted)
+	}
+}
+
+func TestCopyIsiSnapshot_ResolvesSourceVolumeFromSnapshot(t *testing.T) {
+	var gotHeaders map[string]string
+
+	client := &fakeClient{
+		getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+			r, ok := resp.(**getIsiSnapshotsResp)
+			if !ok {
+				t.Fatalf("unexpected resp type %T", resp)
+			}
+			*r = &getIsiSnapshotsResp{
+				Snapshots: []*IsiSnapshot{
+					{Name: "snap-1", Path: "/ifs/data/volumes/vol-1"},
+				},
+			}
+			return nil
+		},
+		putFunc: func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+			gotHeaders = headers
+			return nil
+		},
+	}
+
+	if _, err := CopyIsiSnapshot(context.Background(), client, "snap-1", "vol-1-copy"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "/.snapshot/snap-1/vol-1"
+	got := gotHeaders["x-isi-ifs-copy-source"]
+	if len(got) < len(want) || got[len(got)-len(want):] != want {
+		t.Fatalf("x-isi-ifs-copy-source = %q, want suffix %q", got, want)
+	}
+}
+
+func TestCopyIsiSnapshot_UnknownSnapshot(t *testing.T) {
+	client := &fakeClient{
+		getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+			r := resp.(**getIsiSnapshotsResp)
+			*r = &getIsiSnapshotsResp{}
+			return nil
+		},
+	}
+
+	if _, err := CopyIsiSnapshot(context.Background(), client, "missing-snap", "vol-copy"); err == nil {
+		t.Fatal("expected an error for an unknown source snapshot")
+	}
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"golang.org/x/net/context"
+)
+
+// fakeClient is a test double implementing api.Client, letting tests
+// script per-call responses and errors without making real PAPI
+// round-trips.
+type fakeClient struct {
+	user  string
+	group string
+
+	getFunc    func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error
+	postFunc   func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error
+	putFunc    func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error
+	deleteFunc func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error
+}
+
+func (c *fakeClient) User() string  { return c.user }
+func (c *fakeClient) Group() string { return c.group }
+
+func (c *fakeClient) Get(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+	if c.getFunc == nil {
+		return nil
+	}
+	return c.getFunc(ctx, path, name, params, headers, resp)
+}
+
+func (c *fakeClient) Post(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+	if c.postFunc == nil {
+		return nil
+	}
+	return c.postFunc(ctx, path, name, params, headers, body, resp)
+}
+
+func (c *fakeClient) Put(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+	if c.putFunc == nil {
+		return nil
+	}
+	return c.putFunc(ctx, path, name, params, headers, body, resp)
+}
+
+func (c *fakeClient) Delete(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+	if c.deleteFunc == nil {
+		return nil
+	}
+	return c.deleteFunc(ctx, path, name, params, headers, resp)
+}
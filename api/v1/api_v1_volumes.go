@@ -2,20 +2,16 @@ package v1
 
 import (
 	"fmt"
+	"regexp"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/emccode/goisilon/api"
+	"github.com/emccode/goisilon/api/quotas"
 )
 
-var (
-	createVolumeHeaders = map[string]string{
-		"x-isi-ifs-target-type":    "container",
-		"x-isi-ifs-access-control": "public_read_write",
-	}
-
-	setVolumeACLHeaders = map[string]string{"acl": ""}
-)
+var setVolumeACLHeaders = map[string]string{"acl": ""}
 
 // GetIsiVolumes queries a list of all volumes on the cluster
 func GetIsiVolumes(
@@ -23,15 +19,87 @@ func GetIsiVolumes(
 	client api.Client) (resp *getIsiVolumesResp, err error) {
 
 	// PAPI call: GET https://1.2.3.4:8080/namespace/path/to/volumes/
-	err = client.Get(ctx, realNamespacePath(client), "", nil, nil, &resp)
+	path := realNamespacePath(client)
+	api.LogRequest(ctx, "GET", path, nil)
+	start := time.Now()
+	err = client.Get(ctx, path, "", nil, nil, &resp)
+	api.LogResponse(ctx, "GET", path, time.Since(start), err)
 	return resp, err
 }
 
-// CreateIsiVolume makes a new volume on the cluster
-func CreateIsiVolume(
+// AccessControl selects the x-isi-ifs-access-control header applied when a
+// volume is created.
+type AccessControl string
+
+const (
+	// AccessControlPrivate grants access only to the volume's owner.
+	AccessControlPrivate AccessControl = "private"
+	// AccessControlPublicRead grants read access to everyone.
+	AccessControlPublicRead AccessControl = "public_read"
+	// AccessControlPublicReadWrite grants read and write access to
+	// everyone. This is CreateIsiVolume's historical default.
+	AccessControlPublicReadWrite AccessControl = "public_read_write"
+)
+
+// OwnershipOptions overrides the owner and group applied to a volume
+// instead of the client's default User()/Group(). Note this only changes
+// who ends up owning the resulting directory; the PUTs that create and
+// ACL the volume still authenticate as client's own configured principal.
+// Authenticating a single call as a different Isilon principal would
+// require a per-call credential override on api.Client itself, which this
+// package does not yet expose.
+//
+// TODO(chunk0-5): per-call credential override (as opposed to the
+// ownership-only override above) remains unimplemented, not just
+// deferred-and-forgotten; revisit once api.Client grows a way to
+// authenticate a single call as a different principal.
+type OwnershipOptions struct {
+	Owner *Ownership
+	Group *Ownership
+}
+
+// MountOptions carries extra PAPI headers applied when a volume is
+// created, e.g. a quota size hint or an arbitrary x-isi-ifs-* override,
+// so callers don't have to fork the package to change one header.
+type MountOptions struct {
+	ExtraHeaders map[string]string
+}
+
+// CreateVolumeOptions configures CreateIsiVolumeWithOptions.
+type CreateVolumeOptions struct {
+	Ownership     *OwnershipOptions
+	AccessControl AccessControl
+	MountOptions  *MountOptions
+}
+
+func createVolumeHeadersFor(ac AccessControl, mount *MountOptions) map[string]string {
+	if ac == "" {
+		ac = AccessControlPublicReadWrite
+	}
+
+	headers := map[string]string{
+		"x-isi-ifs-target-type":    "container",
+		"x-isi-ifs-access-control": string(ac),
+	}
+
+	if mount != nil {
+		for k, v := range mount.ExtraHeaders {
+			headers[k] = v
+		}
+	}
+
+	return headers
+}
+
+// CreateIsiVolumeWithOptions makes a new volume on the cluster, applying
+// opts' ownership, access control, and mount headers instead of the
+// client's defaults and the package's hardcoded public_read_write/
+// owner-only behavior.
+func CreateIsiVolumeWithOptions(
 	ctx context.Context,
 	client api.Client,
-	name string) (resp *getIsiVolumesResp, err error) {
+	name string,
+	opts *CreateVolumeOptions) (resp *getIsiVolumesResp, err error) {
 
 	// PAPI calls: PUT https://1.2.3.4:8080/namespace/path/to/volumes/volume_name
 	//             x-isi-ifs-target-type: container
@@ -44,55 +112,162 @@ func CreateIsiVolume(
 	//              group: {name: "groupname", type: "group"}
 	//             }
 
+	if opts == nil {
+		opts = &CreateVolumeOptions{}
+	}
+
+	path := realNamespacePath(client)
+	headers := createVolumeHeadersFor(opts.AccessControl, opts.MountOptions)
+
 	// create the volume
+	api.LogRequest(ctx, "PUT", path, headers)
+	start := time.Now()
 	err = client.Put(
 		ctx,
-		realNamespacePath(client),
+		path,
 		name,
 		nil,
-		createVolumeHeaders,
+		headers,
 		nil,
 		&resp)
+	api.LogResponse(ctx, "PUT", path, time.Since(start), err)
 
 	if err != nil {
 		return resp, err
 	}
 
+	owner := &Ownership{client.User(), "user"}
+	if opts.Ownership != nil && opts.Ownership.Owner != nil {
+		owner = opts.Ownership.Owner
+	}
+
 	var data = &AclRequest{
 		"acl",
 		"update",
-		&Ownership{client.User(), "user"},
+		owner,
 		nil,
 	}
 
-	if group := client.Group(); group != "" {
+	if opts.Ownership != nil && opts.Ownership.Group != nil {
+		data.Group = opts.Ownership.Group
+	} else if group := client.Group(); group != "" {
 		data.Group = &Ownership{group, "group"}
 	}
 
 	// set the ownership of the volume
+	api.LogRequest(ctx, "PUT", path, setVolumeACLHeaders)
+	start = time.Now()
 	err = client.Put(
 		ctx,
-		realNamespacePath(client),
+		path,
 		name,
 		setVolumeACLHeaders,
 		nil,
 		data,
 		&resp)
+	api.LogResponse(ctx, "PUT", path, time.Since(start), err)
 
 	return resp, err
 }
 
-// GetIsiVolume queries the attributes of a volume on the cluster
+// CreateIsiVolume makes a new volume on the cluster owned by the client's
+// default User()/Group(), with public_read_write access control. It is a
+// thin wrapper around CreateIsiVolumeWithOptions kept for backward
+// compatibility.
+func CreateIsiVolume(
+	ctx context.Context,
+	client api.Client,
+	name string) (resp *getIsiVolumesResp, err error) {
+
+	return CreateIsiVolumeWithOptions(ctx, client, name, nil)
+}
+
+// CreateIsiVolumeWithQuota creates a volume exactly as
+// CreateIsiVolumeWithOptions does, then installs a directory quota
+// enforcing sizeBytes as a hard threshold. If the quota create fails, the
+// directory is rolled back so the caller isn't left with an unbounded
+// volume.
+func CreateIsiVolumeWithQuota(
+	ctx context.Context,
+	client api.Client,
+	name string,
+	sizeBytes int64,
+	opts *CreateVolumeOptions) (resp *getIsiVolumesResp, err error) {
+
+	resp, err = CreateIsiVolumeWithOptions(ctx, client, name, opts)
+	if err != nil {
+		return resp, err
+	}
+
+	path := fmt.Sprintf("/%s/%s", realNamespacePath(client), name)
+	if _, err = quotas.CreateIsiQuota(ctx, client, path, sizeBytes, 0, 0, false); err != nil {
+		if _, derr := DeleteIsiVolume(ctx, client, name); derr != nil {
+			return resp, fmt.Errorf(
+				"failed to create quota for volume %q: %v; rollback of volume also failed: %v",
+				name, err, derr)
+		}
+		return resp, fmt.Errorf("failed to create quota for volume %q, volume rolled back: %v", name, err)
+	}
+
+	return resp, nil
+}
+
+// GetIsiVolume queries the attributes of a volume on the cluster.
 func GetIsiVolume(
 	ctx context.Context,
 	client api.Client,
 	name string) (resp *getIsiVolumeAttributesResp, err error) {
 
 	// PAPI call: GET https://1.2.3.4:8080/namespace/path/to/volume/?metadata
-	err = client.Get(ctx, realNamespacePath(client), name, map[string]string{"metadata": ""}, nil, &resp)
+	path := realNamespacePath(client)
+	params := map[string]string{"metadata": ""}
+	api.LogRequest(ctx, "GET", path, nil)
+	start := time.Now()
+	err = client.Get(ctx, path, name, params, nil, &resp)
+	api.LogResponse(ctx, "GET", path, time.Since(start), err)
 	return resp, err
 }
 
+// IsiVolumeAttributes is GetIsiVolumeWithUsage's response, augmented with
+// the volume's current quota usage (if any) so callers can report
+// utilization back to Kubernetes/CSI.
+type IsiVolumeAttributes struct {
+	*getIsiVolumeAttributesResp
+	Usage *quotas.Usage
+}
+
+// GetIsiVolumeWithUsage queries a volume's attributes along with its quota
+// usage, for callers (e.g. a CSI NodeGetVolumeStats implementation) that
+// actually need to report utilization. Unlike GetIsiVolume, this issues a
+// second PAPI round-trip to the quotas subsystem, so it is opt-in rather
+// than baked into every volume read. A volume with no quota installed by
+// CreateIsiVolumeWithQuota leaves Usage nil with no error; any other
+// failure looking up the quota is returned as-is rather than being
+// conflated with "no quota".
+func GetIsiVolumeWithUsage(
+	ctx context.Context,
+	client api.Client,
+	name string) (resp *IsiVolumeAttributes, err error) {
+
+	attrs, err := GetIsiVolume(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &IsiVolumeAttributes{getIsiVolumeAttributesResp: attrs}
+
+	quotaPath := fmt.Sprintf("/%s/%s", realNamespacePath(client), name)
+	quotaResp, err := quotas.ListIsiQuotasByPath(ctx, client, quotaPath)
+	if err != nil {
+		return nil, err
+	}
+	if quotaResp != nil && len(quotaResp.Quotas) > 0 {
+		resp.Usage = quotaResp.Quotas[0].Usage
+	}
+
+	return resp, nil
+}
+
 // DeleteIsiVolume removes a volume from the cluster
 func DeleteIsiVolume(
 	ctx context.Context,
@@ -101,7 +276,11 @@ func DeleteIsiVolume(
 
 	// PAPI call: DELETE https://1.2.3.4:8080/namespace/path/to/volumes/volume_name?recursive=true
 
-	err = client.Delete(ctx, realNamespacePath(client), name, map[string]string{"recursive": "true"}, nil, &resp)
+	path := realNamespacePath(client)
+	api.LogRequest(ctx, "DELETE", path, nil)
+	start := time.Now()
+	err = client.Delete(ctx, path, name, map[string]string{"recursive": "true"}, nil, &resp)
+	api.LogResponse(ctx, "DELETE", path, time.Since(start), err)
 	return resp, err
 }
 
@@ -113,9 +292,227 @@ func CopyIsiVolume(
 	// PAPI calls: PUT https://1.2.3.4:8080/namespace/path/to/volumes/destination_volume_name
 	//             x-isi-ifs-copy-source: /path/to/volumes/source_volume_name
 
-	headers := map[string]string{"x-isi-ifs-copy-source": fmt.Sprintf("/%s/%s", realNamespacePath(client), sourceName)}
+	path := realNamespacePath(client)
+	headers := map[string]string{"x-isi-ifs-copy-source": fmt.Sprintf("/%s/%s", path, sourceName)}
 
 	// copy the volume
-	err = client.Put(ctx, realNamespacePath(client), destinationName, nil, headers, nil, &resp)
+	api.LogRequest(ctx, "PUT", path, headers)
+	start := time.Now()
+	err = client.Put(ctx, path, destinationName, nil, headers, nil, &resp)
+	api.LogResponse(ctx, "PUT", path, time.Since(start), err)
 	return resp, err
 }
+
+// ExistsIsiVolume reports whether a volume with the given name exists on
+// the cluster, cleanly distinguishing a 404 from a transport or other API
+// error instead of requiring callers to string-match GetIsiVolume's error.
+func ExistsIsiVolume(
+	ctx context.Context,
+	client api.Client,
+	name string) (bool, error) {
+
+	_, err := GetIsiVolume(ctx, client, name)
+	if err == nil {
+		return true, nil
+	}
+	if api.IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// VolumeFilter describes the predicates ListIsiVolumesFiltered and
+// PruneIsiVolumes apply when enumerating volumes. A zero-value field means
+// "don't filter on this".
+type VolumeFilter struct {
+	NamePrefix    string
+	NameRegexp    *regexp.Regexp
+	Owner         string
+	Group         string
+	MinSize       int64
+	MaxSize       int64
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	Attributes    map[string]string
+}
+
+func (f *VolumeFilter) hasMetadataPredicate() bool {
+	return f.Owner != "" || f.Group != "" || f.MinSize != 0 || f.MaxSize != 0 ||
+		!f.CreatedBefore.IsZero() || !f.CreatedAfter.IsZero() || len(f.Attributes) > 0
+}
+
+// isiVolumeAttrsResp mirrors the namespace "?metadata" response shape used
+// to evaluate the metadata predicates of a VolumeFilter.
+type isiVolumeAttrsResp struct {
+	Attrs []struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	} `json:"attrs"`
+}
+
+func getIsiVolumeAttrs(
+	ctx context.Context,
+	client api.Client,
+	name string) (map[string]interface{}, error) {
+
+	var resp *isiVolumeAttrsResp
+	path := realNamespacePath(client)
+	api.LogRequest(ctx, "GET", path, nil)
+	start := time.Now()
+	err := client.Get(ctx, path, name, map[string]string{"metadata": ""}, nil, &resp)
+	api.LogResponse(ctx, "GET", path, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]interface{}, len(resp.Attrs))
+	for _, a := range resp.Attrs {
+		attrs[a.Name] = a.Value
+	}
+	return attrs, nil
+}
+
+func attrString(attrs map[string]interface{}, key string) string {
+	s, _ := attrs[key].(string)
+	return s
+}
+
+func attrInt64(attrs map[string]interface{}, key string) int64 {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// volumeMatchesFilter evaluates the metadata predicates of filter against
+// name. The namespace listing used by ListIsiVolumesFiltered already
+// applies NamePrefix and NameRegexp, so this only needs to fetch metadata
+// when a metadata predicate is actually present.
+func volumeMatchesFilter(
+	ctx context.Context,
+	client api.Client,
+	name string,
+	filter *VolumeFilter) (bool, error) {
+
+	if !filter.hasMetadataPredicate() {
+		return true, nil
+	}
+
+	attrs, err := getIsiVolumeAttrs(ctx, client, name)
+	if err != nil {
+		return false, err
+	}
+
+	if filter.Owner != "" && attrString(attrs, "owner") != filter.Owner {
+		return false, nil
+	}
+	if filter.Group != "" && attrString(attrs, "group") != filter.Group {
+		return false, nil
+	}
+	if size := attrInt64(attrs, "size"); (filter.MinSize != 0 && size < filter.MinSize) ||
+		(filter.MaxSize != 0 && size > filter.MaxSize) {
+		return false, nil
+	}
+	if created := attrInt64(attrs, "btime"); created != 0 {
+		createdAt := time.Unix(created, 0)
+		if !filter.CreatedBefore.IsZero() && !createdAt.Before(filter.CreatedBefore) {
+			return false, nil
+		}
+		if !filter.CreatedAfter.IsZero() && !createdAt.After(filter.CreatedAfter) {
+			return false, nil
+		}
+	}
+	for key, want := range filter.Attributes {
+		if attrString(attrs, key) != want {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ListIsiVolumesFiltered queries the namespace for volumes, pushing
+// NamePrefix into the request where the PAPI supports it and applying the
+// rest of filter's predicates client-side against each candidate's
+// metadata.
+func ListIsiVolumesFiltered(
+	ctx context.Context,
+	client api.Client,
+	filter *VolumeFilter) (volumes []string, err error) {
+
+	params := map[string]string{}
+	if filter != nil && filter.NamePrefix != "" {
+		params["prefix"] = filter.NamePrefix
+	}
+
+	path := realNamespacePath(client)
+
+	for resume := ""; ; {
+		if resume != "" {
+			params["resume"] = resume
+		}
+
+		var resp *getIsiVolumesResp
+		api.LogRequest(ctx, "GET", path, nil)
+		start := time.Now()
+		err = client.Get(ctx, path, "", params, nil, &resp)
+		api.LogResponse(ctx, "GET", path, time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range resp.Children {
+			if filter != nil && filter.NameRegexp != nil && !filter.NameRegexp.MatchString(child.Name) {
+				continue
+			}
+
+			if filter != nil {
+				ok, err := volumeMatchesFilter(ctx, client, child.Name, filter)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+
+			volumes = append(volumes, child.Name)
+		}
+
+		if resp.Resume == "" {
+			break
+		}
+		resume = resp.Resume
+	}
+
+	return volumes, nil
+}
+
+// PruneIsiVolumes deletes every volume matching filter, returning the
+// names that were removed and a per-volume error for any that failed to
+// delete.
+func PruneIsiVolumes(
+	ctx context.Context,
+	client api.Client,
+	filter *VolumeFilter) (deleted []string, errs map[string]error) {
+
+	names, err := ListIsiVolumesFiltered(ctx, client, filter)
+	if err != nil {
+		return nil, map[string]error{"": err}
+	}
+
+	errs = map[string]error{}
+	for _, name := range names {
+		if _, err := DeleteIsiVolume(ctx, client, name); err != nil {
+			errs[name] = err
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+
+	return deleted, errs
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestCreateIsiVolumeWithQuota_RollsBackVolumeOnQuotaFailure(t *testing.T) {
+	var deletedVolume string
+
+	client := &fakeClient{
+		putFunc: func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+			return nil // volume create and ACL both succeed
+		},
+		postFunc: func(ctx context.Context, path, name string, params, headers map[string]string, body, resp interface{}) error {
+			return errors.New("quota create failed")
+		},
+		deleteFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+			deletedVolume = name
+			return nil
+		},
+	}
+
+	_, err := CreateIsiVolumeWithQuota(context.Background(), client, "vol-1", 1024, nil)
+	if err == nil {
+		t.Fatal("expected an error when the quota create fails")
+	}
+
+	if deletedVolume != "vol-1" {
+		t.Fatalf("expected the volume to be rolled back, got delete for %q", deletedVolume)
+	}
+}
+
+func TestGetIsiVolumeWithUsage(t *testing.T) {
+	t.Run("no quota installed leaves Usage nil with no error", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				return nil
+			},
+		}
+
+		attrs, err := GetIsiVolumeWithUsage(context.Background(), client, "vol-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if attrs.Usage != nil {
+			t.Fatalf("expected nil Usage, got %v", attrs.Usage)
+		}
+	})
+
+	t.Run("a real quota lookup failure is surfaced, not swallowed", func(t *testing.T) {
+		getCount := 0
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				getCount++
+				if getCount == 1 {
+					return nil // the volume attrs GET succeeds
+				}
+				return errors.New("connection refused") // the quota GET fails
+			},
+		}
+
+		if _, err := GetIsiVolumeWithUsage(context.Background(), client, "vol-1"); err == nil {
+			t.Fatal("expected the quota lookup's transport error to be returned")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+)
+
+func TestExistsIsiVolume(t *testing.T) {
+	t.Run("404 reports false with no error", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				return &api.Error{StatusCode: 404, Code: "AEC_NOT_FOUND", Message: "not found"}
+			},
+		}
+
+		exists, err := ExistsIsiVolume(context.Background(), client, "missing-volume")
+		if err != nil {
+			t.Fatalf("expected no error for a 404, got %v", err)
+		}
+		if exists {
+			t.Fatal("expected ExistsIsiVolume to report false for a 404")
+		}
+	})
+
+	t.Run("transport error is returned, not swallowed", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				return errors.New("connection refused")
+			},
+		}
+
+		exists, err := ExistsIsiVolume(context.Background(), client, "some-volume")
+		if err == nil {
+			t.Fatal("expected the transport error to be returned")
+		}
+		if exists {
+			t.Fatal("expected ExistsIsiVolume to report false on error")
+		}
+	})
+
+	t.Run("success reports true", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				return nil
+			},
+		}
+
+		exists, err := ExistsIsiVolume(context.Background(), client, "some-volume")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !exists {
+			t.Fatal("expected ExistsIsiVolume to report true")
+		}
+	})
+}
+
+func fakeAttrsClient(attrs map[string]interface{}) *fakeClient {
+	return &fakeClient{
+		getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+			r, ok := resp.(**isiVolumeAttrsResp)
+			if !ok {
+				return fmt.Errorf("unexpected resp type %T", resp)
+			}
+			out := &isiVolumeAttrsResp{}
+			for k, v := range attrs {
+				out.Attrs = append(out.Attrs, struct {
+					Name  string      `json:"name"`
+					Value interface{} `json:"value"`
+				}{Name: k, Value: v})
+			}
+			*r = out
+			return nil
+		},
+	}
+}
+
+func TestVolumeMatchesFilter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no metadata predicate skips the GET entirely", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				t.Fatal("metadata should not have been fetched")
+				return nil
+			},
+		}
+		ok, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{})
+		if err != nil || !ok {
+			t.Fatalf("got (%v, %v), want (true, nil)", ok, err)
+		}
+	})
+
+	t.Run("owner mismatch excludes the volume", func(t *testing.T) {
+		client := fakeAttrsClient(map[string]interface{}{"owner": "alice"})
+		ok, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{Owner: "bob"})
+		if err != nil || ok {
+			t.Fatalf("got (%v, %v), want (false, nil)", ok, err)
+		}
+	})
+
+	t.Run("owner match includes the volume", func(t *testing.T) {
+		client := fakeAttrsClient(map[string]interface{}{"owner": "alice"})
+		ok, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{Owner: "alice"})
+		if err != nil || !ok {
+			t.Fatalf("got (%v, %v), want (true, nil)", ok, err)
+		}
+	})
+
+	t.Run("size out of range excludes the volume", func(t *testing.T) {
+		client := fakeAttrsClient(map[string]interface{}{"size": float64(100)})
+		ok, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{MinSize: 200})
+		if err != nil || ok {
+			t.Fatalf("got (%v, %v), want (false, nil)", ok, err)
+		}
+	})
+
+	t.Run("custom attribute mismatch excludes the volume", func(t *testing.T) {
+		client := fakeAttrsClient(map[string]interface{}{"tier": "gold"})
+		ok, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{Attributes: map[string]string{"tier": "silver"}})
+		if err != nil || ok {
+			t.Fatalf("got (%v, %v), want (false, nil)", ok, err)
+		}
+	})
+
+	t.Run("metadata fetch error is returned", func(t *testing.T) {
+		client := &fakeClient{
+			getFunc: func(ctx context.Context, path, name string, params, headers map[string]string, resp interface{}) error {
+				return errors.New("connection refused")
+			},
+		}
+		_, err := volumeMatchesFilter(ctx, client, "vol", &VolumeFilter{Owner: "alice"})
+		if err == nil {
+			t.Fatal("expected the metadata fetch error to be returned")
+		}
+	})
+}
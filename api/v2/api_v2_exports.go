@@ -0,0 +1,229 @@
+package v2
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/emccode/goisilon/api"
+)
+
+const nfsExportsPath = "platform/2/protocols/nfs/exports"
+
+// IsiNfsExportMap identifies the user an NFS client's root or all requests
+// are mapped to.
+type IsiNfsExportMap struct {
+	User    string `json:"user"`
+	Enabled bool   `json:"enabled"`
+}
+
+// IsiNfsExport represents a single NFS export as returned by the PAPI.
+type IsiNfsExport struct {
+	ID              int              `json:"id"`
+	Paths           []string         `json:"paths"`
+	Clients         []string         `json:"clients,omitempty"`
+	RootClients     []string         `json:"root_clients,omitempty"`
+	ReadOnlyClients []string         `json:"read_only_clients,omitempty"`
+	MapRoot         *IsiNfsExportMap `json:"map_root,omitempty"`
+	MapAll          *IsiNfsExportMap `json:"map_all,omitempty"`
+	SecurityFlavors []string         `json:"security_flavors,omitempty"`
+}
+
+// IsiNfsExportOptions carries the fields of an NFS export that callers may
+// set on create or update.
+type IsiNfsExportOptions struct {
+	Paths           []string
+	Clients         []string
+	RootClients     []string
+	ReadOnlyClients []string
+	MapRoot         *IsiNfsExportMap
+	MapAll          *IsiNfsExportMap
+	SecurityFlavors []string
+}
+
+type isiNfsExportBody struct {
+	Paths           []string         `json:"paths,omitempty"`
+	Clients         []string         `json:"clients,omitempty"`
+	RootClients     []string         `json:"root_clients,omitempty"`
+	ReadOnlyClients []string         `json:"read_only_clients,omitempty"`
+	MapRoot         *IsiNfsExportMap `json:"map_root,omitempty"`
+	MapAll          *IsiNfsExportMap `json:"map_all,omitempty"`
+	SecurityFlavors []string         `json:"security_flavors,omitempty"`
+}
+
+func newIsiNfsExportBody(opts *IsiNfsExportOptions) *isiNfsExportBody {
+	if opts == nil {
+		return &isiNfsExportBody{}
+	}
+	return &isiNfsExportBody{
+		Paths:           opts.Paths,
+		Clients:         opts.Clients,
+		RootClients:     opts.RootClients,
+		ReadOnlyClients: opts.ReadOnlyClients,
+		MapRoot:         opts.MapRoot,
+		MapAll:          opts.MapAll,
+		SecurityFlavors: opts.SecurityFlavors,
+	}
+}
+
+type createIsiNfsExportResp struct {
+	ID int `json:"id"`
+}
+
+type getIsiNfsExportsResp struct {
+	Exports []*IsiNfsExport `json:"exports"`
+	Total   int             `json:"total"`
+	Resume  string          `json:"resume,omitempty"`
+}
+
+// CreateIsiNfsExport creates a new NFS export for one or more paths and
+// returns its ID.
+func CreateIsiNfsExport(
+	ctx context.Context,
+	client api.Client,
+	opts *IsiNfsExportOptions) (id int, err error) {
+
+	// PAPI call: POST https://1.2.3.4:8080/platform/2/protocols/nfs/exports
+	var resp *createIsiNfsExportResp
+	api.LogRequest(ctx, "POST", nfsExportsPath, nil)
+	start := time.Now()
+	err = client.Post(ctx, nfsExportsPath, "", nil, nil, newIsiNfsExportBody(opts), &resp)
+	api.LogResponse(ctx, "POST", nfsExportsPath, time.Since(start), err)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// GetIsiNfsExports queries all NFS exports on the cluster.
+func GetIsiNfsExports(
+	ctx context.Context,
+	client api.Client) (resp *getIsiNfsExportsResp, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/2/protocols/nfs/exports
+	api.LogRequest(ctx, "GET", nfsExportsPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, nfsExportsPath, "", nil, nil, &resp)
+	api.LogResponse(ctx, "GET", nfsExportsPath, time.Since(start), err)
+	return resp, err
+}
+
+// GetIsiNfsExport queries a single NFS export by ID.
+func GetIsiNfsExport(
+	ctx context.Context,
+	client api.Client,
+	id int) (export *IsiNfsExport, err error) {
+
+	// PAPI call: GET https://1.2.3.4:8080/platform/2/protocols/nfs/exports/id
+	var resp *getIsiNfsExportsResp
+	api.LogRequest(ctx, "GET", nfsExportsPath, nil)
+	start := time.Now()
+	err = client.Get(ctx, nfsExportsPath, fmt.Sprintf("%d", id), nil, nil, &resp)
+	api.LogResponse(ctx, "GET", nfsExportsPath, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Exports) == 0 {
+		return nil, fmt.Errorf("nfs export %d not found", id)
+	}
+	return resp.Exports[0], nil
+}
+
+// GetIsiNfsExportByPath queries all NFS exports and returns the one whose
+// Paths includes path, letting a caller that only knows a volume's path
+// resolve its export without having tracked the export ID itself.
+func GetIsiNfsExportByPath(
+	ctx context.Context,
+	client api.Client,
+	path string) (export *IsiNfsExport, err error) {
+
+	resp, err := GetIsiNfsExports(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, export := range resp.Exports {
+		for _, p := range export.Paths {
+			if p == path {
+				return export, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("nfs export for path %q not found", path)
+}
+
+// UpdateIsiNfsExport sets the non-empty fields of opts on an existing
+// export. Fields left empty in opts are not sent and keep their current
+// values.
+func UpdateIsiNfsExport(
+	ctx context.Context,
+	client api.Client,
+	id int,
+	opts *IsiNfsExportOptions) error {
+
+	// PAPI call: PUT https://1.2.3.4:8080/platform/2/protocols/nfs/exports/id
+	api.LogRequest(ctx, "PUT", nfsExportsPath, nil)
+	start := time.Now()
+	err := client.Put(ctx, nfsExportsPath, fmt.Sprintf("%d", id), nil, nil, newIsiNfsExportBody(opts), nil)
+	api.LogResponse(ctx, "PUT", nfsExportsPath, time.Since(start), err)
+	return err
+}
+
+// DeleteIsiNfsExport removes an NFS export by ID.
+func DeleteIsiNfsExport(
+	ctx context.Context,
+	client api.Client,
+	id int) error {
+
+	// PAPI call: DELETE https://1.2.3.4:8080/platform/2/protocols/nfs/exports/id
+	api.LogRequest(ctx, "DELETE", nfsExportsPath, nil)
+	start := time.Now()
+	err := client.Delete(ctx, nfsExportsPath, fmt.Sprintf("%d", id), nil, nil, nil)
+	api.LogResponse(ctx, "DELETE", nfsExportsPath, time.Since(start), err)
+	return err
+}
+
+// AddIsiNfsExportClients appends clients to an existing export's client
+// list, so a controller can attach a node without racing on a full PUT of
+// the current list.
+func AddIsiNfsExportClients(
+	ctx context.Context,
+	client api.Client,
+	id int,
+	clients []string) error {
+
+	data := &struct {
+		Clients []string `json:"add_clients"`
+	}{Clients: clients}
+
+	// PAPI call: PUT https://1.2.3.4:8080/platform/2/protocols/nfs/exports/id
+	//            {add_clients: [...]}
+	api.LogRequest(ctx, "PUT", nfsExportsPath, nil)
+	start := time.Now()
+	err := client.Put(ctx, nfsExportsPath, fmt.Sprintf("%d", id), nil, nil, data, nil)
+	api.LogResponse(ctx, "PUT", nfsExportsPath, time.Since(start), err)
+	return err
+}
+
+// RemoveIsiNfsExportClients removes clients from an existing export's
+// client list.
+func RemoveIsiNfsExportClients(
+	ctx context.Context,
+	client api.Client,
+	id int,
+	clients []string) error {
+
+	data := &struct {
+		Clients []string `json:"remove_clients"`
+	}{Clients: clients}
+
+	// PAPI call: PUT https://1.2.3.4:8080/platform/2/protocols/nfs/exports/id
+	//            {remove_clients: [...]}
+	api.LogRequest(ctx, "PUT", nfsExportsPath, nil)
+	start := time.Now()
+	err := client.Put(ctx, nfsExportsPath, fmt.Sprintf("%d", id), nil, nil, data, nil)
+	api.LogResponse(ctx, "PUT", nfsExportsPath, time.Since(start), err)
+	return err
+}